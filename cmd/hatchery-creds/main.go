@@ -2,21 +2,27 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
-	"github.com/docker/docker/api/types/events"
-	"github.com/docker/docker/api/types/filters"
 	"github.com/levinkeller/hatchery/internal/config"
 	"github.com/levinkeller/hatchery/internal/creds"
 	"github.com/levinkeller/hatchery/internal/drone"
-	"github.com/levinkeller/hatchery/internal/zerg"
+	"github.com/levinkeller/hatchery/internal/events"
+	"github.com/levinkeller/hatchery/internal/metrics"
 )
 
+// adminStatus adapts a SocketManager and Watcher to metrics.StatusSource.
+type adminStatus struct {
+	sm      *creds.SocketManager
+	watcher *events.Watcher
+}
+
+func (s adminStatus) SocketsOpen() int       { return len(s.sm.Names()) }
+func (s adminStatus) EventStreamStatus() any { return s.watcher.Status() }
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -38,12 +44,26 @@ func main() {
 
 	sm := creds.NewSocketManager(cfg.SocketDir, tp)
 
+	cli, err := drone.NewClient()
+	if err != nil {
+		log.Fatalf("connecting to Docker: %v", err)
+	}
+	defer cli.Close()
+
+	watcher := events.NewWatcher(cli)
+	watcher.OnStart = func(droneName string, repos, services []string) {
+		if err := sm.CreateSocket(droneName, repos, services); err != nil {
+			log.Printf("failed to create socket for %s: %v", droneName, err)
+		}
+	}
+	watcher.OnStop = sm.RemoveSocket
+	watcher.ListKnownDrones = sm.Names
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Recovery: recreate sockets for all existing drones
-	if err := recover(ctx, sm); err != nil {
-		log.Printf("recovery warning: %v", err)
+	if err := metrics.Serve(ctx, cfg.AdminAddr, adminStatus{sm: sm, watcher: watcher}); err != nil {
+		log.Printf("starting admin listener: %v", err)
 	}
 
 	// Graceful shutdown
@@ -56,95 +76,5 @@ func main() {
 		cancel()
 	}()
 
-	// Watch Docker events
-	watchEvents(ctx, sm)
-}
-
-// recover queries Docker for existing hatchery drones and creates sockets for them.
-func recover(ctx context.Context, sm *creds.SocketManager) error {
-	zerg.Printf(zerg.MsgRecovering)
-
-	cli, err := drone.NewClient()
-	if err != nil {
-		return fmt.Errorf("connecting to Docker: %w", err)
-	}
-	defer cli.Close()
-
-	drones, err := drone.ListDrones(ctx, cli)
-	if err != nil {
-		return fmt.Errorf("listing drones: %w", err)
-	}
-
-	for _, d := range drones {
-		if d.State == "running" {
-			repos := reposFromDrone(d)
-			if err := sm.CreateSocket(d.Name, repos); err != nil {
-				log.Printf("failed to recover socket for %s: %v", d.Name, err)
-			}
-		}
-	}
-
-	return nil
-}
-
-// watchEvents subscribes to Docker container events and manages sockets accordingly.
-func watchEvents(ctx context.Context, sm *creds.SocketManager) {
-	cli, err := drone.NewClient()
-	if err != nil {
-		log.Fatalf("connecting to Docker for events: %v", err)
-	}
-	defer cli.Close()
-
-	f := filters.NewArgs()
-	f.Add("type", string(events.ContainerEventType))
-	f.Add("label", drone.LabelManaged+"=true")
-
-	eventCh, errCh := cli.Events(ctx, events.ListOptions{Filters: f})
-
-	log.Println("watching Docker events...")
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errCh:
-			if err != nil && ctx.Err() == nil {
-				log.Printf("Docker event stream error: %v", err)
-			}
-			return
-		case event := <-eventCh:
-			droneName := event.Actor.Attributes[drone.LabelDrone]
-			if droneName == "" {
-				continue
-			}
-
-			switch event.Action {
-			case events.ActionStart:
-				repo := event.Actor.Attributes[drone.LabelRepo]
-				repos := parseRepos(repo)
-				if err := sm.CreateSocket(droneName, repos); err != nil {
-					log.Printf("failed to create socket for %s: %v", droneName, err)
-				}
-			case events.ActionStop, events.ActionDie:
-				sm.RemoveSocket(droneName)
-			}
-		}
-	}
-}
-
-// reposFromDrone extracts the repo list from a drone's labels.
-func reposFromDrone(d drone.Drone) []string {
-	return parseRepos(d.Repo)
-}
-
-// parseRepos splits a comma-separated repo string into a slice.
-func parseRepos(repo string) []string {
-	if repo == "" {
-		return nil
-	}
-	repos := strings.Split(repo, ",")
-	for i := range repos {
-		repos[i] = strings.TrimSpace(repos[i])
-	}
-	return repos
+	watcher.Run(ctx)
 }
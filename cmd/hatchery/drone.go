@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	hcli "github.com/levinkeller/hatchery/internal/cli"
+	"github.com/levinkeller/hatchery/internal/drone"
+	"github.com/levinkeller/hatchery/internal/zerg"
+)
+
+// droneCmd groups commands that operate on a single drone.
+func droneCmd(cctx *hcli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "drone",
+		Short:       "Spawn, slay, and inspect individual drones",
+		Annotations: map[string]string{"category": hcli.CategoryManagement},
+	}
+	cmd.AddCommand(droneSpawnCmd(cctx), droneSlayCmd(cctx), droneStatusCmd(cctx), droneCheckpointCmd(cctx), droneRestoreCmd(cctx))
+	return cmd
+}
+
+func droneSpawnCmd(cctx *hcli.Context) *cobra.Command {
+	var ref string
+	var depth int
+	var submodules bool
+	var credentialsRef string
+
+	cmd := &cobra.Command{
+		Use:   "spawn <repo-source>",
+		Short: "Spawn a new drone from a repository",
+		Long: "Spawn a new drone from a repository. repo-source accepts a bare\n" +
+			"\"org/repo\" (GitHub), a \"<provider>:org/repo\" short form (github,\n" +
+			"gitlab, gitea, bitbucket), a full git URL, or \"local:///host/path\".",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := cctx.Runtime()
+			if err != nil {
+				return err
+			}
+			return drone.Spawn(cctx.Ctx, drone.SpawnOptions{
+				Repo: drone.RepoSource{
+					URL:            args[0],
+					Ref:            ref,
+					Depth:          depth,
+					Submodules:     submodules,
+					CredentialsRef: credentialsRef,
+				},
+				Config:  cctx.Config,
+				Runtime: rt,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&ref, "ref", "", "branch or tag to check out (default: remote's default branch)")
+	cmd.Flags().IntVar(&depth, "depth", 1, "shallow clone depth")
+	cmd.Flags().BoolVar(&submodules, "submodules", false, "recursively clone submodules")
+	cmd.Flags().StringVar(&credentialsRef, "credentials", "", "SSH key path, or hatchery-creds socket name, to authenticate the clone")
+
+	return cmd
+}
+
+func droneStatusCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <org/repo>",
+		Short: "Show status of a specific drone",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			name := drone.DroneName(drone.RepoSource{URL: args[0]})
+			d, err := drone.FindDrone(cctx.Ctx, cli, name)
+			if err != nil {
+				return err
+			}
+			if d == nil {
+				return fmt.Errorf(zerg.MsgDroneNotFound)
+			}
+
+			fmt.Println(zerg.Status(d.Name, d.State))
+			fmt.Printf("  Repo:      %s\n", d.Repo)
+			fmt.Printf("  Container: %s\n", d.ID[:12])
+			return nil
+		},
+	}
+}
+
+func droneSlayCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "slay <org/repo>",
+		Short: "Remove a drone permanently",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			name := drone.DroneName(drone.RepoSource{URL: args[0]})
+			d, err := drone.FindDrone(cctx.Ctx, cli, name)
+			if err != nil {
+				return err
+			}
+			if d == nil {
+				return fmt.Errorf(zerg.MsgDroneNotFound)
+			}
+
+			if err := drone.RemoveDrone(cctx.Ctx, cli, d.ID); err != nil {
+				return err
+			}
+
+			// Tear down sidecar services and their network, if any
+			if len(d.Services) > 0 {
+				drone.RemoveServices(cctx.Ctx, cli, d.Services)
+				_ = drone.RemoveNetwork(cctx.Ctx, cli, name)
+			}
+
+			// Clean up the volume
+			volName := drone.VolumeName(name)
+			_ = cli.VolumeRemove(cctx.Ctx, volName, true)
+
+			zerg.Printf(zerg.MsgSlayComplete)
+			return nil
+		},
+	}
+}
+
+func droneCheckpointCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "checkpoint <org/repo> <out-path>",
+		Short: "Archive a drone's workspace and stop it, for later restore",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			name := drone.DroneName(drone.RepoSource{URL: args[0]})
+			return drone.Checkpoint(cctx.Ctx, cli, name, args[1])
+		},
+	}
+}
+
+func droneRestoreCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <archive-path>",
+		Short: "Recreate a drone from a checkpoint archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			return drone.Restore(cctx.Ctx, cli, args[0], drone.RestoreOptions{Config: cctx.Config})
+		},
+	}
+}
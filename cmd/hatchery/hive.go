@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	hcli "github.com/levinkeller/hatchery/internal/cli"
+	"github.com/levinkeller/hatchery/internal/drone"
+	"github.com/levinkeller/hatchery/internal/zerg"
+)
+
+// hiveCmd groups commands that inspect or bulk-manage the whole hive.
+func hiveCmd(cctx *hcli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "hive",
+		Short:       "List and bulk-manage the whole hive",
+		Annotations: map[string]string{"category": hcli.CategoryManagement},
+	}
+	cmd.AddCommand(hiveListCmd(cctx), hivePauseCmd(cctx), hiveResumeCmd(cctx))
+	return cmd
+}
+
+func hiveListCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all drones in the hive",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			drones, err := drone.ListDrones(cctx.Ctx, cli)
+			if err != nil {
+				return err
+			}
+			if len(drones) == 0 {
+				zerg.Printf(zerg.MsgNoDrones)
+				return nil
+			}
+			for _, d := range drones {
+				fmt.Println(zerg.Status(d.Name, d.State))
+			}
+			return nil
+		},
+	}
+}
+
+func hivePauseCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause <org/repo>",
+		Short: "Stop a drone (burrow underground)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			name := drone.DroneName(drone.RepoSource{URL: args[0]})
+			d, err := drone.FindDrone(cctx.Ctx, cli, name)
+			if err != nil {
+				return err
+			}
+			if d == nil {
+				return fmt.Errorf(zerg.MsgDroneNotFound)
+			}
+
+			if err := drone.StopDrone(cctx.Ctx, cli, d.ID); err != nil {
+				return err
+			}
+			zerg.Printf(zerg.MsgBurrowComplete)
+			return nil
+		},
+	}
+}
+
+func hiveResumeCmd(cctx *hcli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume <org/repo>",
+		Short: "Start a stopped drone (emerge from the ground)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := cctx.Client()
+			if err != nil {
+				return err
+			}
+
+			name := drone.DroneName(drone.RepoSource{URL: args[0]})
+			d, err := drone.FindDrone(cctx.Ctx, cli, name)
+			if err != nil {
+				return err
+			}
+			if d == nil {
+				return fmt.Errorf(zerg.MsgDroneNotFound)
+			}
+
+			if err := drone.StartDrone(cctx.Ctx, cli, d.ID); err != nil {
+				return err
+			}
+			zerg.Printf(zerg.MsgUnburrowComplete)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+// Package cli holds state and conventions shared across the hatchery
+// command-line tool's command groups.
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/client"
+
+	"github.com/levinkeller/hatchery/internal/config"
+	"github.com/levinkeller/hatchery/internal/drone"
+	"github.com/levinkeller/hatchery/internal/zerg"
+)
+
+// Context carries state shared across CLI commands so each RunE doesn't
+// re-parse config or re-detect the container runtime.
+type Context struct {
+	Config *config.Config
+	Ctx    context.Context
+
+	runtime drone.Runtime
+}
+
+// NewContext loads config once and wraps ctx for command use.
+func NewContext(ctx context.Context) (*Context, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Context{Config: cfg, Ctx: ctx}, nil
+}
+
+// Runtime lazily detects the container engine (Docker or Podman) on first
+// use and reuses it for the rest of the invocation, so every command
+// targets whichever engine a drone was actually spawned against.
+func (c *Context) Runtime() (drone.Runtime, error) {
+	if c.runtime != nil {
+		return c.runtime, nil
+	}
+	rt, err := drone.DetectRuntime()
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", zerg.MsgDockerError, err)
+	}
+	c.runtime = rt
+	return rt, nil
+}
+
+// Client returns the detected runtime's Docker-API-compatible client.
+func (c *Context) Client() (*client.Client, error) {
+	rt, err := c.Runtime()
+	if err != nil {
+		return nil, err
+	}
+	return rt.Client(), nil
+}
+
+// Close releases any runtime connection Client/Runtime opened.
+func (c *Context) Close() {
+	if c.runtime != nil {
+		c.runtime.Close()
+	}
+}
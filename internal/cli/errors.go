@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/levinkeller/hatchery/internal/zerg"
+)
+
+// Exit codes, distinct so shell scripts can tell a flag-parsing failure
+// (ExitFlagError) from a failed operation (ExitRuntimeError) such as a
+// missing drone or an unreachable Docker daemon. Other usage errors, such
+// as a wrong number of arguments, exit with ExitRuntimeError.
+const (
+	ExitRuntimeError = 1
+	ExitFlagError    = 2
+)
+
+// FlagError wraps a cobra flag-parsing failure so main can map it to
+// ExitFlagError instead of the generic runtime exit code.
+type FlagError struct {
+	cmd *cobra.Command
+	err error
+}
+
+func (e *FlagError) Error() string {
+	return fmt.Sprintf("%s %v\n\n%s", zerg.MsgBadOrders, e.err, e.cmd.UsageString())
+}
+
+func (e *FlagError) Unwrap() error {
+	return e.err
+}
+
+// FlagErrorFunc is installed as the root command's FlagErrorFunc so bad
+// flags surface with a themed message and a distinct exit code instead of
+// being indistinguishable from a runtime failure.
+func FlagErrorFunc(cmd *cobra.Command, err error) error {
+	return &FlagError{cmd: cmd, err: err}
+}
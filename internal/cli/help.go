@@ -0,0 +1,43 @@
+package cli
+
+import "github.com/spf13/cobra"
+
+// CategoryManagement marks a top-level command as one of the grouped
+// management commands (drone/hive/token) for the usage template below,
+// mirroring the management-vs-operation split Docker's own CLI uses.
+const CategoryManagement = "management"
+
+const usageTemplate = `Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+Aliases:
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+Examples:
+{{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
+Management Commands:{{range .Commands}}{{if (and .IsAvailableCommand (eq (index .Annotations "category") "` + CategoryManagement + `"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}
+
+Commands:{{range .Commands}}{{if (and .IsAvailableCommand (ne (index .Annotations "category") "` + CategoryManagement + `"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespace}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespace}}{{end}}{{if .HasHelpSubCommands}}
+
+Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
+  {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+`
+
+// SetHelpTemplate installs a usage template on root that renders the
+// grouped management commands (drone/hive/token) separately from cobra's
+// own built-ins (help, completion).
+func SetHelpTemplate(root *cobra.Command) {
+	root.SetUsageTemplate(usageTemplate)
+}
@@ -46,6 +46,8 @@ type Config struct {
 	HeadscaleAuthKey     string
 	TailscaleDomain      string // e.g. "tail.levinkeller.de"
 	SocketDir            string // e.g. "/var/run/hatchery"
+	TrustedKeys          string // dir of PEM public keys, or comma-separated PEM file paths, for hatchery.sig verification
+	AdminAddr            string // admin HTTP listener for /metrics and /status; empty disables it
 }
 
 func Load() (*Config, error) {
@@ -59,6 +61,7 @@ func Load() (*Config, error) {
 		HeadscaleAuthKey:     os.Getenv("HATCHERY_HEADSCALE_AUTH_KEY"),
 		TailscaleDomain:      os.Getenv("HATCHERY_TAILSCALE_DOMAIN"),
 		SocketDir:            os.Getenv("HATCHERY_SOCKET_DIR"),
+		TrustedKeys:          os.Getenv("HATCHERY_TRUSTED_KEYS"),
 	}
 
 	if cfg.TailscaleDomain == "" {
@@ -68,6 +71,14 @@ func Load() (*Config, error) {
 		cfg.SocketDir = "/var/run/hatchery"
 	}
 
+	// Distinguish "unset" (apply the default) from "explicitly set to
+	// empty" (disable the admin listener).
+	if addr, ok := os.LookupEnv("HATCHERY_ADMIN_ADDR"); ok {
+		cfg.AdminAddr = addr
+	} else {
+		cfg.AdminAddr = "127.0.0.1:9110"
+	}
+
 	// If key looks like a file path, read it
 	if len(cfg.GitHubAppPrivateKey) > 0 && cfg.GitHubAppPrivateKey[0] == '/' {
 		data, err := os.ReadFile(cfg.GitHubAppPrivateKey)
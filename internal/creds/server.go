@@ -8,10 +8,16 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/levinkeller/hatchery/internal/metrics"
 	"github.com/levinkeller/hatchery/internal/zerg"
 )
 
+// tokenSweepInterval controls how often SocketManager prunes expired token
+// cache entries in the background.
+const tokenSweepInterval = 5 * time.Minute
+
 // SocketManager manages per-drone Unix socket HTTP servers.
 type SocketManager struct {
 	socketDir     string
@@ -19,25 +25,55 @@ type SocketManager struct {
 
 	mu      sync.Mutex
 	sockets map[string]*socketEntry // drone name -> entry
+
+	stopSweep chan struct{}
 }
 
 type socketEntry struct {
 	listener net.Listener
 	server   *http.Server
 	repos    []string
+	services []string // names of sidecar service containers spawned for this drone
+
+	mu        sync.Mutex
+	lastToken string // most recently served token, revoked when the socket goes away
 }
 
-// NewSocketManager creates a new socket manager.
+// NewSocketManager creates a new socket manager and starts its background
+// token cache sweeper.
 func NewSocketManager(socketDir string, tp *TokenProvider) *SocketManager {
-	return &SocketManager{
+	sm := &SocketManager{
 		socketDir:     socketDir,
 		tokenProvider: tp,
 		sockets:       make(map[string]*socketEntry),
+		stopSweep:     make(chan struct{}),
 	}
+	go sm.sweepExpiredTokens()
+	return sm
 }
 
-// CreateSocket creates a Unix socket for a drone and starts an HTTP server on it.
-func (sm *SocketManager) CreateSocket(droneName string, repos []string) error {
+// sweepExpiredTokens periodically prunes token cache entries whose
+// ExpiresAt has passed, so a long-lived hatchery-creds process doesn't
+// accumulate dead entries between socket lifecycle events.
+func (sm *SocketManager) sweepExpiredTokens() {
+	ticker := time.NewTicker(tokenSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sm.tokenProvider.PruneExpired()
+		case <-sm.stopSweep:
+			return
+		}
+	}
+}
+
+// CreateSocket creates a Unix socket for a drone and starts an HTTP server on
+// it. services records the names of any sidecar containers hatchery.yaml
+// spawned alongside the drone, so recovery can re-associate them after a
+// hatchery-creds restart.
+func (sm *SocketManager) CreateSocket(droneName string, repos []string, services []string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -61,6 +97,11 @@ func (sm *SocketManager) CreateSocket(droneName string, repos []string) error {
 		return fmt.Errorf("chmod socket: %w", err)
 	}
 
+	entry := &socketEntry{
+		repos:    repos,
+		services: services,
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
 		token, err := sm.tokenProvider.GetToken(repos)
@@ -68,18 +109,19 @@ func (sm *SocketManager) CreateSocket(droneName string, repos []string) error {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		entry.mu.Lock()
+		entry.lastToken = token
+		entry.mu.Unlock()
 		w.Header().Set("Content-Type", "text/plain")
 		fmt.Fprint(w, token)
 	})
 
 	srv := &http.Server{Handler: mux}
-	entry := &socketEntry{
-		listener: listener,
-		server:   srv,
-		repos:    repos,
-	}
+	entry.listener = listener
+	entry.server = srv
 
 	sm.sockets[droneName] = entry
+	metrics.SocketsOpen.Inc()
 
 	go func() {
 		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
@@ -91,34 +133,70 @@ func (sm *SocketManager) CreateSocket(droneName string, repos []string) error {
 	return nil
 }
 
-// RemoveSocket stops the HTTP server and removes the socket file for a drone.
-func (sm *SocketManager) RemoveSocket(droneName string) {
+// Names returns the drone names that currently have an open socket.
+func (sm *SocketManager) Names() []string {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	names := make([]string, 0, len(sm.sockets))
+	for name := range sm.sockets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// RemoveSocket stops the HTTP server, removes the socket file for a drone,
+// and revokes the most recent token it handed out.
+func (sm *SocketManager) RemoveSocket(droneName string) {
+	sm.mu.Lock()
 	entry, exists := sm.sockets[droneName]
 	if !exists {
+		sm.mu.Unlock()
 		return
 	}
+	delete(sm.sockets, droneName)
+	sm.mu.Unlock()
+	metrics.SocketsOpen.Dec()
 
 	entry.server.Close()
-	delete(sm.sockets, droneName)
 
 	socketPath := filepath.Join(sm.socketDir, droneName+".sock")
 	os.Remove(socketPath)
 
+	sm.revokeEntryToken(droneName, entry)
+
 	zerg.Printf("%s [%s]", zerg.MsgSocketRemoved, droneName)
 }
 
-// Shutdown gracefully closes all sockets.
+// Shutdown gracefully closes all sockets, stops the sweeper, and revokes
+// each socket's most recent token.
 func (sm *SocketManager) Shutdown(ctx context.Context) {
+	close(sm.stopSweep)
+
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
+	sockets := sm.sockets
+	sm.sockets = make(map[string]*socketEntry)
+	sm.mu.Unlock()
+	metrics.SocketsOpen.Sub(float64(len(sockets)))
 
-	for name, entry := range sm.sockets {
+	for name, entry := range sockets {
 		entry.server.Shutdown(ctx)
 		socketPath := filepath.Join(sm.socketDir, name+".sock")
 		os.Remove(socketPath)
+		sm.revokeEntryToken(name, entry)
+	}
+}
+
+// revokeEntryToken revokes entry's last-served token, if it ever served one.
+func (sm *SocketManager) revokeEntryToken(droneName string, entry *socketEntry) {
+	entry.mu.Lock()
+	token := entry.lastToken
+	entry.mu.Unlock()
+
+	if token == "" {
+		return
+	}
+	if err := sm.tokenProvider.RevokeToken(token); err != nil {
+		fmt.Fprintf(os.Stderr, "revoking token for %s: %v\n", droneName, err)
 	}
-	sm.sockets = make(map[string]*socketEntry)
 }
@@ -9,18 +9,26 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/levinkeller/hatchery/internal/metrics"
 )
 
+// defaultAPIBaseURL is the real GitHub API; tests override apiBaseURL to
+// point at an httptest.Server instead.
+const defaultAPIBaseURL = "https://api.github.com"
+
 // TokenProvider generates scoped GitHub App installation access tokens.
 type TokenProvider struct {
 	appID          string
 	installationID string
 	privateKey     *rsa.PrivateKey
+	apiBaseURL     string
 
 	mu    sync.Mutex
 	cache map[string]cachedToken // key = sorted repo names
@@ -54,6 +62,20 @@ func NewTokenProvider(appID, installationID, privateKeyPEM string) (*TokenProvid
 // GetToken returns a scoped installation access token for the given repos.
 // Returns a cached token if >5min remaining, otherwise generates a new one.
 func (tp *TokenProvider) GetToken(repos []string) (string, error) {
+	token, _, err := tp.getTokenWithExpiry(repos)
+	return token, err
+}
+
+// Inspect returns a scoped installation access token for the given repos
+// along with its expiry, for callers (e.g. `hatchery token inspect`) that
+// want to report more than the bare token.
+func (tp *TokenProvider) Inspect(repos []string) (string, time.Time, error) {
+	return tp.getTokenWithExpiry(repos)
+}
+
+// getTokenWithExpiry is the shared cache-or-mint path behind GetToken and
+// Inspect.
+func (tp *TokenProvider) getTokenWithExpiry(repos []string) (string, time.Time, error) {
 	key := cacheKey(repos)
 
 	tp.mu.Lock()
@@ -61,17 +83,19 @@ func (tp *TokenProvider) GetToken(repos []string) (string, error) {
 
 	if cached, ok := tp.cache[key]; ok {
 		if time.Until(cached.ExpiresAt) > 5*time.Minute {
-			return cached.Token, nil
+			metrics.TokenCacheHits.Inc()
+			return cached.Token, cached.ExpiresAt, nil
 		}
 	}
+	metrics.TokenCacheMisses.Inc()
 
 	token, expiresAt, err := tp.createInstallationToken(repos)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	tp.cache[key] = cachedToken{Token: token, ExpiresAt: expiresAt}
-	return token, nil
+	return token, expiresAt, nil
 }
 
 // createJWT creates a short-lived JWT signed with the app's private key.
@@ -94,7 +118,7 @@ func (tp *TokenProvider) createInstallationToken(repos []string) (string, time.T
 		return "", time.Time{}, fmt.Errorf("creating JWT: %w", err)
 	}
 
-	url := fmt.Sprintf("https://api.github.com/app/installations/%s/access_tokens", tp.installationID)
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", tp.baseURL(), tp.installationID)
 
 	body := map[string]any{
 		"repositories": repoNames(repos),
@@ -109,7 +133,7 @@ func (tp *TokenProvider) createInstallationToken(repos []string) (string, time.T
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := doGitHubRequest(req)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("GitHub API request: %w", err)
 	}
@@ -131,6 +155,76 @@ func (tp *TokenProvider) createInstallationToken(repos []string) (string, time.T
 	return result.Token, result.ExpiresAt, nil
 }
 
+// RevokeToken invalidates an installation access token immediately via the
+// GitHub API and evicts any cache entry still holding it, so a drone that's
+// been slain or died can't keep using a token for the rest of its hour.
+func (tp *TokenProvider) RevokeToken(token string) error {
+	req, err := http.NewRequest("DELETE", tp.baseURL()+"/installation/token", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := doGitHubRequest(req)
+	if err != nil {
+		return fmt.Errorf("revoking token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API returned %d revoking token: %s", resp.StatusCode, string(body))
+	}
+
+	tp.mu.Lock()
+	for key, cached := range tp.cache {
+		if cached.Token == token {
+			delete(tp.cache, key)
+			break
+		}
+	}
+	tp.mu.Unlock()
+
+	return nil
+}
+
+// PruneExpired removes cache entries whose token has already expired. It
+// doesn't call the GitHub API — an expired token is already dead there.
+func (tp *TokenProvider) PruneExpired() {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	now := time.Now()
+	for key, cached := range tp.cache {
+		if now.After(cached.ExpiresAt) {
+			delete(tp.cache, key)
+		}
+	}
+}
+
+// baseURL returns the configured GitHub API base, defaulting to the real API.
+func (tp *TokenProvider) baseURL() string {
+	if tp.apiBaseURL != "" {
+		return tp.apiBaseURL
+	}
+	return defaultAPIBaseURL
+}
+
+// doGitHubRequest performs req against the GitHub API, recording request
+// count (by response code) and latency metrics.
+func doGitHubRequest(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	metrics.GitHubAPIDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.GitHubAPIRequests.WithLabelValues("error").Inc()
+		return nil, err
+	}
+	metrics.GitHubAPIRequests.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+	return resp, nil
+}
+
 // cacheKey returns a stable key for a set of repos.
 func cacheKey(repos []string) string {
 	sorted := make([]string, len(repos))
@@ -0,0 +1,81 @@
+package creds
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRevokeToken(t *testing.T) {
+	var gotMethod, gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tp := &TokenProvider{
+		apiBaseURL: server.URL,
+		cache: map[string]cachedToken{
+			"org/repo": {Token: "tok-123", ExpiresAt: time.Now().Add(time.Hour)},
+		},
+	}
+
+	if err := tp.RevokeToken("tok-123"); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+
+	if gotMethod != http.MethodDelete {
+		t.Errorf("method = %q, want DELETE", gotMethod)
+	}
+	if gotPath != "/installation/token" {
+		t.Errorf("path = %q, want /installation/token", gotPath)
+	}
+	if gotAuth != "Bearer tok-123" {
+		t.Errorf("Authorization = %q, want Bearer tok-123", gotAuth)
+	}
+
+	tp.mu.Lock()
+	_, cached := tp.cache["org/repo"]
+	tp.mu.Unlock()
+	if cached {
+		t.Error("cache entry for revoked token was not evicted")
+	}
+}
+
+func TestRevokeTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("bad credentials"))
+	}))
+	defer server.Close()
+
+	tp := &TokenProvider{apiBaseURL: server.URL, cache: map[string]cachedToken{}}
+
+	if err := tp.RevokeToken("tok-123"); err == nil {
+		t.Fatal("expected an error from a non-204 response")
+	}
+}
+
+func TestPruneExpired(t *testing.T) {
+	tp := &TokenProvider{
+		cache: map[string]cachedToken{
+			"expired": {Token: "old", ExpiresAt: time.Now().Add(-time.Minute)},
+			"fresh":   {Token: "new", ExpiresAt: time.Now().Add(time.Hour)},
+		},
+	}
+
+	tp.PruneExpired()
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if _, ok := tp.cache["expired"]; ok {
+		t.Error("expired cache entry was not pruned")
+	}
+	if _, ok := tp.cache["fresh"]; !ok {
+		t.Error("fresh cache entry was incorrectly pruned")
+	}
+}
@@ -0,0 +1,294 @@
+package drone
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	dockererrdefs "github.com/docker/docker/errdefs"
+
+	"github.com/levinkeller/hatchery/internal/config"
+	"github.com/levinkeller/hatchery/internal/errdefs"
+	"github.com/levinkeller/hatchery/internal/spec"
+	"github.com/levinkeller/hatchery/internal/zerg"
+)
+
+// Manifest is a checkpoint's sidecar record of everything Restore needs
+// besides the workspace volume's contents itself.
+type Manifest struct {
+	Name       string   `json:"name"`
+	Repo       string   `json:"repo"`
+	Services   []string `json:"services,omitempty"`
+	RepoScopes []string `json:"repoScopes,omitempty"`
+}
+
+// manifestPath derives a checkpoint's sidecar manifest path from its
+// archive path.
+func manifestPath(archivePath string) string {
+	return archivePath + ".json"
+}
+
+// Checkpoint stops a drone and archives its workspace volume to outPath as
+// a gzipped tar, alongside a sidecar JSON manifest (outPath + ".json")
+// describing the drone well enough for Restore to recreate it. The
+// container itself is left stopped, not removed, so a failed checkpoint
+// doesn't lose the drone.
+//
+// Only the workspace volume is archived; this repo's devcontainers don't
+// currently mount a separate persistent home volume, so there's nothing
+// under /home/node to include yet.
+func Checkpoint(ctx context.Context, cli *client.Client, name, outPath string) error {
+	d, err := FindDrone(ctx, cli, name)
+	if err != nil {
+		return fmt.Errorf("%s %w", zerg.MsgDockerError, err)
+	}
+	if d == nil {
+		return errdefs.NotFound(fmt.Errorf(zerg.MsgDroneNotFound))
+	}
+
+	zerg.Printf(zerg.MsgCheckpointing)
+	if err := StopDrone(ctx, cli, d.ID); err != nil {
+		return fmt.Errorf("stopping drone: %w", err)
+	}
+
+	volName := VolumeName(name)
+	if err := tarVolumeToFile(ctx, cli, volName, outPath); err != nil {
+		return fmt.Errorf("archiving volume: %w", err)
+	}
+
+	manifest := Manifest{
+		Name:       name,
+		Repo:       d.Repo,
+		Services:   d.Services,
+		RepoScopes: d.RepoScopes,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath(outPath), data, 0644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	zerg.Printf(zerg.MsgCheckpointDone)
+	return nil
+}
+
+// RestoreOptions configures recreating a drone from a checkpoint.
+type RestoreOptions struct {
+	Config *config.Config
+}
+
+// Restore recreates a drone from a checkpoint written by Checkpoint: a
+// fresh volume is untarred from archivePath, the hatchery.yaml-derived
+// override config is rebuilt, and devcontainerUp is invoked against a new
+// Tailscale hostname. The drone's name is taken from the checkpoint's
+// manifest, so it's restored under its original identity.
+func Restore(ctx context.Context, cli *client.Client, archivePath string, opts RestoreOptions) error {
+	data, err := os.ReadFile(manifestPath(archivePath))
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("parsing manifest: %w", err)
+	}
+
+	existing, err := FindDrone(ctx, cli, manifest.Name)
+	if err != nil {
+		return fmt.Errorf("%s %w", zerg.MsgDockerError, err)
+	}
+	if existing != nil {
+		return errdefs.AlreadyExists(fmt.Errorf(zerg.MsgDroneExists))
+	}
+
+	zerg.Printf(zerg.MsgRestoring)
+	volName := VolumeName(manifest.Name)
+	if _, err := cli.VolumeCreate(ctx, volume.CreateOptions{Name: volName}); err != nil {
+		if dockererrdefs.IsConflict(err) {
+			return errdefs.AlreadyExists(fmt.Errorf("creating volume: %w", err))
+		}
+		return fmt.Errorf("creating volume: %w", err)
+	}
+	if err := untarFileToVolume(ctx, cli, archivePath, volName); err != nil {
+		_ = cli.VolumeRemove(ctx, volName, true)
+		return fmt.Errorf("restoring volume: %w", err)
+	}
+
+	src, err := resolve(RepoSource{URL: manifest.Repo})
+	if err != nil {
+		_ = cli.VolumeRemove(ctx, volName, true)
+		return errdefs.InvalidArg(err)
+	}
+
+	var droneSpec *spec.Spec
+	if src.Provider == "github" {
+		droneSpec, err = fetchSpec(ctx, src.Name, opts.Config)
+		if err != nil {
+			zerg.Printf("Warning: not honoring hatchery.yaml for %s: %v", src.Name, err)
+			droneSpec = nil
+		}
+	}
+
+	var netName string
+	var serviceNames []string
+	if len(manifest.Services) > 0 {
+		if droneSpec == nil || len(droneSpec.Services) == 0 {
+			_ = cli.VolumeRemove(ctx, volName, true)
+			return fmt.Errorf("drone %s had services %v at checkpoint time, but no fetchable hatchery.yaml declares them now — refusing to restore without connectivity", manifest.Name, manifest.Services)
+		}
+
+		if _, err := CreateNetwork(ctx, cli, manifest.Name); err != nil {
+			_ = cli.VolumeRemove(ctx, volName, true)
+			return err
+		}
+		netName = NetworkName(manifest.Name)
+
+		serviceNames, err = SpawnServices(ctx, cli, manifest.Name, netName, droneSpec.Services)
+		if err != nil {
+			_ = RemoveNetwork(ctx, cli, manifest.Name)
+			_ = cli.VolumeRemove(ctx, volName, true)
+			return fmt.Errorf("spawning services: %w", err)
+		}
+	}
+
+	tsHostname := manifest.Name + "-" + restoreSuffix()
+
+	overrideDir, err := writeOverrideConfig(manifest.Name, tsHostname, src.Name, netName, serviceNames, droneSpec, opts.Config)
+	if err != nil {
+		RemoveServices(ctx, cli, serviceNames)
+		if netName != "" {
+			_ = RemoveNetwork(ctx, cli, manifest.Name)
+		}
+		return fmt.Errorf("writing override config: %w", err)
+	}
+	defer os.RemoveAll(overrideDir)
+
+	if err := devcontainerUp(ctx, cli.DaemonHost(), volName, overrideDir); err != nil {
+		RemoveServices(ctx, cli, serviceNames)
+		if netName != "" {
+			_ = RemoveNetwork(ctx, cli, manifest.Name)
+		}
+		return fmt.Errorf("devcontainer up: %w", err)
+	}
+
+	hostname := Hostname(tsHostname, opts.Config.TailscaleDomain)
+	zerg.Printf(zerg.MsgWaitingTailscale)
+	if err := waitForHost(ctx, hostname, 120*time.Second); err != nil {
+		zerg.Printf("Warning: Tailscale hostname %s not yet resolvable: %v", hostname, err)
+	}
+
+	zerg.Printf(zerg.MsgRestoreDone)
+	zerg.Printf("  ssh -p 2222 node@%s", hostname)
+	return nil
+}
+
+// restoreSuffix returns a short random token distinguishing a restored
+// drone's Tailscale hostname from the one it was checkpointed under, so a
+// restore doesn't collide with (or silently reuse the identity of) a drone
+// still registered under the original hostname elsewhere.
+func restoreSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// tarVolumeToFile archives a volume's contents into a gzipped tar at
+// outPath, via an ephemeral alpine container and the Docker API's
+// tar-stream copy endpoint. CopyFromContainer reads straight off the
+// volume's filesystem rather than through the container logging driver, so
+// it isn't subject to log rotation truncating a large archive, and works
+// regardless of which logging driver the daemon is configured with.
+func tarVolumeToFile(ctx context.Context, cli *client.Client, volName, outPath string) error {
+	reader, err := cli.ImagePull(ctx, "alpine", image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pulling alpine: %w", err)
+	}
+	reader.Close()
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image: "alpine",
+	}, &container.HostConfig{
+		Binds: []string{volName + ":/workspaces:ro"},
+	}, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("creating archive container: %w", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+
+	tarStream, _, err := cli.CopyFromContainer(ctx, resp.ID, "/workspaces")
+	if err != nil {
+		return fmt.Errorf("reading volume: %w", err)
+	}
+	defer tarStream.Close()
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	if _, err := io.Copy(gz, tarStream); err != nil {
+		return fmt.Errorf("writing %s: %w", outPath, err)
+	}
+	return gz.Close()
+}
+
+// untarFileToVolume extracts a gzipped tar written by tarVolumeToFile into
+// a volume, via an ephemeral container and the Docker API's tar-stream
+// copy endpoint. The archive's entries are rooted at "workspaces/...", from
+// CopyFromContainer's own directory naming, so it's extracted at "/" with
+// the volume bound at /workspaces rather than directly at "/workspaces".
+func untarFileToVolume(ctx context.Context, cli *client.Client, archivePath, volName string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", archivePath, err)
+	}
+	defer gz.Close()
+
+	var tarball bytes.Buffer
+	if _, err := io.Copy(&tarball, gz); err != nil {
+		return fmt.Errorf("decompressing %s: %w", archivePath, err)
+	}
+
+	reader, err := cli.ImagePull(ctx, "alpine", image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pulling alpine: %w", err)
+	}
+	reader.Close()
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image: "alpine",
+		Cmd:   []string{"true"},
+	}, &container.HostConfig{
+		Binds: []string{volName + ":/workspaces"},
+	}, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("creating restore container: %w", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+
+	if err := cli.CopyToContainer(ctx, resp.ID, "/", &tarball, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("copying archive into volume: %w", err)
+	}
+
+	return nil
+}
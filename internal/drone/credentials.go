@@ -0,0 +1,94 @@
+package drone
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/levinkeller/hatchery/internal/config"
+)
+
+// cloneCredentials resolves a RepoSource's CredentialsRef into the extra
+// binds and environment a clone container needs to authenticate: an
+// absolute path is used as an SSH private key, anything else is taken as
+// the name of a running hatchery-creds socket and its token is baked into
+// a one-shot GIT_ASKPASS helper. cleanup removes any temp files written to
+// the host and must be called once the clone container has finished.
+func cloneCredentials(ctx context.Context, ref string, cfg *config.Config) (binds, env []string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	if filepath.IsAbs(ref) {
+		binds = []string{ref + ":/root/.ssh/clone_key:ro"}
+		env = []string{"GIT_SSH_COMMAND=ssh -i /root/.ssh/clone_key -o StrictHostKeyChecking=no"}
+		return binds, env, cleanup, nil
+	}
+
+	token, err := readSocketToken(ctx, filepath.Join(cfg.SocketDir, ref+".sock"))
+	if err != nil {
+		return nil, nil, cleanup, fmt.Errorf("reading credentials socket %s: %w", ref, err)
+	}
+
+	askpassPath, err := writeAskpassScript(token)
+	if err != nil {
+		return nil, nil, cleanup, fmt.Errorf("writing askpass helper: %w", err)
+	}
+	cleanup = func() { os.Remove(askpassPath) }
+
+	binds = []string{askpassPath + ":/usr/local/bin/hatchery-askpass.sh:ro"}
+	env = []string{"GIT_ASKPASS=/usr/local/bin/hatchery-askpass.sh"}
+	return binds, env, cleanup, nil
+}
+
+// readSocketToken fetches the current token from a hatchery-creds unix
+// socket, the same one the devcontainer's github-creds.sock bind serves.
+func readSocketToken(ctx context.Context, socketPath string) (string, error) {
+	c := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unix/token", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("socket returned %d: %s", resp.StatusCode, body)
+	}
+	return string(body), nil
+}
+
+// writeAskpassScript writes a GIT_ASKPASS helper that echoes token, for a
+// single clone's worth of HTTPS credential prompts.
+func writeAskpassScript(token string) (string, error) {
+	f, err := os.CreateTemp("", "hatchery-askpass-*.sh")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "#!/bin/sh\necho %q\n", token); err != nil {
+		return "", err
+	}
+	if err := f.Chmod(0755); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -2,6 +2,7 @@ package drone
 
 import (
 	"context"
+	"strings"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -13,7 +14,8 @@ func NewClient() (*client.Client, error) {
 	return client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 }
 
-// ListDrones returns all containers with the hatchery.managed label.
+// ListDrones returns all devcontainers managed by hatchery, excluding their
+// sidecar service containers.
 func ListDrones(ctx context.Context, cli *client.Client) ([]Drone, error) {
 	f := filters.NewArgs()
 	f.Add("label", LabelManaged+"=true")
@@ -28,17 +30,37 @@ func ListDrones(ctx context.Context, cli *client.Client) ([]Drone, error) {
 
 	drones := make([]Drone, 0, len(containers))
 	for _, c := range containers {
+		if c.Labels[LabelServiceOf] != "" {
+			continue
+		}
 		drones = append(drones, Drone{
-			Name:  c.Labels[LabelDrone],
-			Repo:  c.Labels[LabelRepo],
-			ID:    c.ID,
-			State: c.State,
+			Name:       c.Labels[LabelDrone],
+			Repo:       c.Labels[LabelRepo],
+			ID:         c.ID,
+			State:      c.State,
+			Services:   ParseLabelList(c.Labels[LabelServices]),
+			RepoScopes: ParseLabelList(c.Labels[LabelRepoScopes]),
 		})
 	}
 	return drones, nil
 }
 
-// FindDrone finds a specific drone by name.
+// ParseLabelList splits a comma-separated label value, e.g. hatchery.services
+// or hatchery.repo, into its component names.
+func ParseLabelList(label string) []string {
+	if label == "" {
+		return nil
+	}
+	names := strings.Split(label, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// FindDrone finds a specific drone's devcontainer by name. Its sidecar
+// service containers carry LabelServiceOf, not LabelDrone, so they never
+// match here.
 func FindDrone(ctx context.Context, cli *client.Client, name string) (*Drone, error) {
 	f := filters.NewArgs()
 	f.Add("label", LabelManaged+"=true")
@@ -57,10 +79,12 @@ func FindDrone(ctx context.Context, cli *client.Client, name string) (*Drone, er
 
 	c := containers[0]
 	return &Drone{
-		Name:  c.Labels[LabelDrone],
-		Repo:  c.Labels[LabelRepo],
-		ID:    c.ID,
-		State: c.State,
+		Name:       c.Labels[LabelDrone],
+		Repo:       c.Labels[LabelRepo],
+		ID:         c.ID,
+		State:      c.State,
+		Services:   ParseLabelList(c.Labels[LabelServices]),
+		RepoScopes: ParseLabelList(c.Labels[LabelRepoScopes]),
 	}, nil
 }
 
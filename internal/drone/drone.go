@@ -1,27 +1,39 @@
 package drone
 
-import (
-	"strings"
-)
-
 // Docker label constants
 const (
-	LabelManaged = "hatchery.managed"
-	LabelDrone   = "hatchery.drone"
-	LabelRepo    = "hatchery.repo"
+	LabelManaged    = "hatchery.managed"
+	LabelDrone      = "hatchery.drone"
+	LabelRepo       = "hatchery.repo"
+	LabelServices   = "hatchery.services"
+	LabelRepoScopes = "hatchery.repo-scopes"
+
+	// LabelServiceOf marks a sidecar service container, set to the name of
+	// the drone it belongs to. Its devcontainer carries LabelDrone instead,
+	// never both, so the two are easy to tell apart in container listings.
+	LabelServiceOf = "hatchery.service-of"
 )
 
 // Drone represents a managed devcontainer.
 type Drone struct {
-	Name  string // e.g. "levinkeller-homepage"
-	Repo  string // e.g. "levinkeller/homepage"
-	ID    string // Docker container ID
-	State string // running, exited, etc.
+	Name       string   // e.g. "levinkeller-homepage"
+	Repo       string   // e.g. "levinkeller/homepage"
+	ID         string   // Docker container ID
+	State      string   // running, exited, etc.
+	Services   []string // names of sidecar service containers, if any
+	RepoScopes []string // extra repos the broker token should cover, from a verified hatchery.yaml
+}
+
+// NetworkName returns the per-drone Docker network name used to connect the
+// devcontainer to its sidecar services.
+func NetworkName(name string) string {
+	return "hatchery-" + name
 }
 
-// DroneName converts "org/repo" to "org-repo".
-func DroneName(repo string) string {
-	return strings.ReplaceAll(repo, "/", "-")
+// ServiceContainerName returns the container name for a sidecar service
+// belonging to a drone.
+func ServiceContainerName(droneName, serviceName string) string {
+	return "hatchery-" + droneName + "-" + serviceName
 }
 
 // VolumeName returns the Docker volume name for a drone.
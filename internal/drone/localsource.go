@@ -0,0 +1,98 @@
+package drone
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+// copyLocalDir populates a drone's workspace volume from a host directory,
+// for "local://" repo sources. It tars the directory in memory and streams
+// it into an ephemeral container's bind-mounted volume, since the Docker
+// API has no direct host-path-to-volume copy.
+func copyLocalDir(ctx context.Context, cli *client.Client, dir, volName string) error {
+	tarball, err := tarDir(dir)
+	if err != nil {
+		return fmt.Errorf("archiving %s: %w", dir, err)
+	}
+
+	reader, err := cli.ImagePull(ctx, "alpine/git", image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pulling alpine/git: %w", err)
+	}
+	reader.Close()
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image: "alpine/git",
+		Cmd:   []string{"true"},
+	}, &container.HostConfig{
+		Binds: []string{volName + ":/workspace"},
+	}, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("creating copy container: %w", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+
+	if err := cli.CopyToContainer(ctx, resp.ID, "/workspace", tarball, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("copying %s into volume: %w", dir, err)
+	}
+
+	return nil
+}
+
+// tarDir archives dir's contents (not dir itself) into an in-memory tar
+// stream suitable for CopyToContainer.
+func tarDir(dir string) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
@@ -0,0 +1,141 @@
+package drone
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/levinkeller/hatchery/internal/pipeline"
+	"github.com/levinkeller/hatchery/internal/zerg"
+)
+
+// pipelineFile is the optional pipeline definition hatchery looks for in a
+// freshly cloned repo. It's independent of the signed hatchery.yaml fetched
+// via the GitHub API: this one lives in the repo's own tree and is read
+// straight out of the workspace volume.
+const pipelineFile = ".hatchery.yml"
+
+// loadPipeline reads .hatchery.yml out of a drone's workspace volume via an
+// ephemeral container, since the volume isn't otherwise host-accessible. A
+// missing file is not an error — the pipeline is optional.
+func loadPipeline(ctx context.Context, cli *client.Client, volName string) ([]pipeline.Step, error) {
+	reader, err := cli.ImagePull(ctx, "alpine/git", image.PullOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("pulling alpine/git: %w", err)
+	}
+	reader.Close()
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image: "alpine/git",
+		Cmd:   []string{"cat", "/workspace/" + pipelineFile},
+	}, &container.HostConfig{
+		Binds: []string{volName + ":/workspace:ro"},
+	}, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("creating pipeline read container: %w", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("starting pipeline read container: %w", err)
+	}
+
+	waitCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case result := <-waitCh:
+		if result.StatusCode != 0 {
+			return nil, nil // no .hatchery.yml in this repo
+		}
+	case err := <-errCh:
+		return nil, fmt.Errorf("waiting for pipeline read: %w", err)
+	}
+
+	out, err := cli.ContainerLogs(ctx, resp.ID, container.LogsOptions{ShowStdout: true})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", pipelineFile, err)
+	}
+	defer out.Close()
+
+	var stdout bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, io.Discard, out); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", pipelineFile, err)
+	}
+
+	return pipeline.Load(stdout.Bytes())
+}
+
+// runPipeline runs every step in steps whose When matches phase (one of
+// pipeline.WhenPreDevcontainer or pipeline.WhenPostDevcontainer), each in
+// its own ephemeral container with the drone's workspace volume mounted at
+// /workspace. It streams each step's stdout and stderr to hatchery's own
+// stdout and stderr and aborts on the first non-zero exit.
+func runPipeline(ctx context.Context, cli *client.Client, volName string, steps []pipeline.Step, phase string) error {
+	for _, step := range steps {
+		if step.When != phase {
+			continue
+		}
+
+		zerg.Printf("%s [%s]", zerg.MsgPipelineRunning, step.Name)
+
+		if err := runPipelineStep(ctx, cli, volName, step); err != nil {
+			return fmt.Errorf("%s step %q: %w", zerg.MsgPipelineFailed, step.Name, err)
+		}
+	}
+	return nil
+}
+
+func runPipelineStep(ctx context.Context, cli *client.Client, volName string, step pipeline.Step) error {
+	reader, err := cli.ImagePull(ctx, step.Image, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pulling %s: %w", step.Image, err)
+	}
+	reader.Close()
+
+	env := make([]string, 0, len(step.Environment))
+	for k, v := range step.Environment {
+		env = append(env, k+"="+v)
+	}
+
+	binds := append([]string{volName + ":/workspace"}, step.Volumes...)
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:      step.Image,
+		Cmd:        []string{"sh", "-c", strings.Join(step.Commands, " && ")},
+		Env:        env,
+		WorkingDir: "/workspace",
+	}, &container.HostConfig{
+		Binds: binds,
+	}, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("creating container: %w", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("starting container: %w", err)
+	}
+
+	if out, err := cli.ContainerLogs(ctx, resp.ID, container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true}); err == nil {
+		stdcopy.StdCopy(os.Stdout, os.Stderr, out)
+		out.Close()
+	}
+
+	waitCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case result := <-waitCh:
+		if result.StatusCode != 0 {
+			return fmt.Errorf("exited with status %d", result.StatusCode)
+		}
+		return nil
+	case err := <-errCh:
+		return fmt.Errorf("waiting for container: %w", err)
+	}
+}
@@ -0,0 +1,96 @@
+package drone
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RepoSource describes where to clone a drone's working tree from.
+type RepoSource struct {
+	// URL is one of:
+	//   - a short "<provider>:owner/name" form resolved via the provider
+	//     registry (github, gitlab, gitea, bitbucket)
+	//   - a bare "owner/name", hatchery's original shorthand, defaulting
+	//     to GitHub
+	//   - a full git URL (https://, git@host:owner/name, ...)
+	//   - "local:///host/path", which tar-streams a host directory into
+	//     the drone's volume instead of cloning
+	URL string
+
+	// Ref is the branch or tag to check out, passed to git clone --branch;
+	// a bare commit hash is not accepted there and fails the clone. Empty
+	// means the remote's default branch.
+	Ref string
+
+	// Depth is the shallow clone depth. 0 defaults to 1.
+	Depth int
+
+	// Submodules recursively clones submodules when true.
+	Submodules bool
+
+	// CredentialsRef authenticates the clone: an absolute path is taken
+	// as an SSH private key, anything else names a socket under
+	// cfg.SocketDir whose /token endpoint is used as an HTTPS askpass
+	// credential.
+	CredentialsRef string
+}
+
+// resolvedSource is a RepoSource with its provider, repo name, and clone
+// URL (or local source directory) worked out.
+type resolvedSource struct {
+	Provider string // "github", "gitlab", "gitea", "bitbucket", "git", or "local"
+	Name     string // "owner/repo", or the local directory's base name
+	CloneURL string // unused for local sources
+	LocalDir string // host directory to tar-stream, for local:// sources
+}
+
+// providerHosts maps a short-form provider prefix to the host its HTTPS
+// clone URLs live under.
+var providerHosts = map[string]string{
+	"github":    "github.com",
+	"gitlab":    "gitlab.com",
+	"gitea":     "gitea.com",
+	"bitbucket": "bitbucket.org",
+}
+
+// resolve expands a RepoSource's URL into its provider, repo name, and
+// either the URL to clone or the local directory to tar-stream.
+func resolve(src RepoSource) (resolvedSource, error) {
+	if src.URL == "" {
+		return resolvedSource{}, fmt.Errorf("empty repo source")
+	}
+
+	if dir, ok := strings.CutPrefix(src.URL, "local://"); ok {
+		return resolvedSource{Provider: "local", Name: filepath.Base(filepath.Clean(dir)), LocalDir: dir}, nil
+	}
+
+	if provider, name, ok := strings.Cut(src.URL, ":"); ok && !strings.Contains(provider, "/") {
+		if host, known := providerHosts[provider]; known {
+			return resolvedSource{Provider: provider, Name: name, CloneURL: "https://" + host + "/" + name + ".git"}, nil
+		}
+	}
+
+	if !strings.Contains(src.URL, "://") && !strings.Contains(src.URL, "@") && strings.Count(src.URL, "/") == 1 {
+		// Bare "owner/repo": hatchery's original shorthand, defaults to GitHub.
+		return resolvedSource{Provider: "github", Name: src.URL, CloneURL: "https://github.com/" + src.URL + ".git"}, nil
+	}
+
+	// A literal URL we don't otherwise recognize: clone it as-is.
+	return resolvedSource{
+		Provider: "git",
+		Name:     strings.TrimSuffix(path.Base(src.URL), ".git"),
+		CloneURL: src.URL,
+	}, nil
+}
+
+// DroneName derives a drone's name from a repo source, disambiguating
+// repos that share an "owner/name" across different providers.
+func DroneName(src RepoSource) string {
+	r, err := resolve(src)
+	if err != nil {
+		return "invalid-repo-source"
+	}
+	return strings.ReplaceAll(r.Provider+"-"+r.Name, "/", "-")
+}
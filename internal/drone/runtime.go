@@ -0,0 +1,97 @@
+package drone
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+)
+
+// Runtime is the container engine a drone is spawned against. Docker and
+// Podman both speak the same container-engine API (Podman's REST API is a
+// Docker-API-compatible superset), so the only thing that actually differs
+// between them is which socket to dial and what DOCKER_HOST to hand the
+// devcontainer CLI — volume create, image pull, container run/wait, and
+// label-based listing already work unmodified against either, via the same
+// *client.Client.
+type Runtime interface {
+	// Client returns the Docker-API client dialed at this runtime's socket.
+	Client() *client.Client
+	// Host is the DOCKER_HOST-style endpoint this runtime is dialed at, so
+	// devcontainerUp can pass it through to the devcontainer CLI.
+	Host() string
+	Close() error
+}
+
+type apiRuntime struct {
+	cli  *client.Client
+	host string
+}
+
+func (r *apiRuntime) Client() *client.Client { return r.cli }
+func (r *apiRuntime) Host() string           { return r.host }
+func (r *apiRuntime) Close() error           { return r.cli.Close() }
+
+// DockerRuntime dials the standard Docker daemon socket (or $DOCKER_HOST).
+type DockerRuntime struct{ apiRuntime }
+
+// NewDockerRuntime creates a Runtime against the Docker daemon.
+func NewDockerRuntime() (*DockerRuntime, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+	return &DockerRuntime{apiRuntime{cli: cli, host: cli.DaemonHost()}}, nil
+}
+
+// PodmanRuntime dials a Podman socket, honoring $CONTAINER_HOST and, for
+// rootless Podman, $XDG_RUNTIME_DIR/podman/podman.sock.
+type PodmanRuntime struct{ apiRuntime }
+
+// NewPodmanRuntime creates a Runtime against a Podman socket.
+func NewPodmanRuntime() (*PodmanRuntime, error) {
+	host := podmanHost()
+	cli, err := client.NewClientWithOpts(client.WithHost(host), client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+	return &PodmanRuntime{apiRuntime{cli: cli, host: host}}, nil
+}
+
+// rootlessSocket returns the path of the current user's rootless Podman
+// socket, honoring $XDG_RUNTIME_DIR and falling back to the conventional
+// /run/user/<uid> when it's unset.
+func rootlessSocket() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return filepath.Join(runtimeDir, "podman", "podman.sock")
+}
+
+// podmanHost resolves the Podman socket to dial: $CONTAINER_HOST if set,
+// otherwise the rootless per-user socket if it exists, otherwise the
+// rootful system socket.
+func podmanHost() string {
+	if h := os.Getenv("CONTAINER_HOST"); h != "" {
+		return h
+	}
+	if _, err := os.Stat(rootlessSocket()); err == nil {
+		return "unix://" + rootlessSocket()
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// DetectRuntime picks Docker or Podman from the environment: an explicit
+// $CONTAINER_HOST or an existing rootless Podman socket file means Podman,
+// otherwise Docker.
+func DetectRuntime() (Runtime, error) {
+	if os.Getenv("CONTAINER_HOST") != "" {
+		return NewPodmanRuntime()
+	}
+	if _, err := os.Stat(rootlessSocket()); err == nil {
+		return NewPodmanRuntime()
+	}
+	return NewDockerRuntime()
+}
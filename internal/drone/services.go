@@ -0,0 +1,97 @@
+package drone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	dockererrdefs "github.com/docker/docker/errdefs"
+	"github.com/levinkeller/hatchery/internal/spec"
+)
+
+// CreateNetwork creates the per-drone Docker network that the devcontainer
+// and its sidecar services join.
+func CreateNetwork(ctx context.Context, cli *client.Client, name string) (string, error) {
+	netName := NetworkName(name)
+	resp, err := cli.NetworkCreate(ctx, netName, network.CreateOptions{
+		Labels: map[string]string{
+			LabelManaged: "true",
+			LabelDrone:   name,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating network %s: %w", netName, err)
+	}
+	return resp.ID, nil
+}
+
+// RemoveNetwork removes a drone's sidecar network, ignoring "not found" errors.
+func RemoveNetwork(ctx context.Context, cli *client.Client, name string) error {
+	err := cli.NetworkRemove(ctx, NetworkName(name))
+	if dockererrdefs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// SpawnServices pulls and starts the sidecar containers declared in a spec,
+// attaching each to the drone's network under its service name as the
+// network alias. It returns the names of the containers it started; on any
+// failure it tears down everything it already started.
+func SpawnServices(ctx context.Context, cli *client.Client, droneName, netName string, services []spec.Service) ([]string, error) {
+	started := make([]string, 0, len(services))
+
+	for _, svc := range services {
+		reader, err := cli.ImagePull(ctx, svc.Image, image.PullOptions{})
+		if err != nil {
+			RemoveServices(ctx, cli, started)
+			return nil, fmt.Errorf("pulling %s: %w", svc.Image, err)
+		}
+		reader.Close()
+
+		containerName := ServiceContainerName(droneName, svc.Name)
+		env := make([]string, 0, len(svc.Env))
+		for k, v := range svc.Env {
+			env = append(env, k+"="+v)
+		}
+
+		resp, err := cli.ContainerCreate(ctx, &container.Config{
+			Image: svc.Image,
+			Env:   env,
+			Labels: map[string]string{
+				LabelManaged:   "true",
+				LabelServiceOf: droneName,
+			},
+		}, &container.HostConfig{
+			NetworkMode: container.NetworkMode(netName),
+		}, &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				netName: {Aliases: []string{svc.Name}},
+			},
+		}, nil, containerName)
+		if err != nil {
+			RemoveServices(ctx, cli, started)
+			return nil, fmt.Errorf("creating service %s: %w", svc.Name, err)
+		}
+
+		if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+			RemoveServices(ctx, cli, started)
+			return nil, fmt.Errorf("starting service %s: %w", svc.Name, err)
+		}
+
+		started = append(started, containerName)
+	}
+
+	return started, nil
+}
+
+// RemoveServices force-removes the named sidecar containers, ignoring
+// "not found" errors so teardown is idempotent.
+func RemoveServices(ctx context.Context, cli *client.Client, containerNames []string) {
+	for _, name := range containerNames {
+		_ = cli.ContainerRemove(ctx, name, container.RemoveOptions{Force: true})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,19 +16,48 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	dockererrdefs "github.com/docker/docker/errdefs"
 	"github.com/levinkeller/hatchery/internal/config"
+	"github.com/levinkeller/hatchery/internal/creds"
+	"github.com/levinkeller/hatchery/internal/errdefs"
+	"github.com/levinkeller/hatchery/internal/pipeline"
+	"github.com/levinkeller/hatchery/internal/spec"
 	"github.com/levinkeller/hatchery/internal/zerg"
 )
 
 // SpawnOptions configures a new drone.
 type SpawnOptions struct {
-	Repo   string
+	Repo   RepoSource
 	Config *config.Config
+
+	// Runtime is the container engine to spawn against. If nil, it's
+	// auto-detected from $CONTAINER_HOST / the presence of a rootless
+	// Podman socket, falling back to Docker.
+	Runtime Runtime
+
+	// Pipeline overrides the build steps run around devcontainer startup.
+	// If nil, it's loaded from a .hatchery.yml in the cloned repo, if any.
+	Pipeline []pipeline.Step
 }
 
 // Spawn creates a new drone: volume, clone, override config, devcontainer up, wait for tailscale.
-func Spawn(ctx context.Context, cli *client.Client, opts SpawnOptions) error {
-	name := DroneName(opts.Repo)
+func Spawn(ctx context.Context, opts SpawnOptions) error {
+	rt := opts.Runtime
+	if rt == nil {
+		var err error
+		rt, err = DetectRuntime()
+		if err != nil {
+			return fmt.Errorf("%s %w", zerg.MsgDockerError, err)
+		}
+		defer rt.Close()
+	}
+	cli := rt.Client()
+
+	src, err := resolve(opts.Repo)
+	if err != nil {
+		return errdefs.InvalidArg(err)
+	}
+	name := strings.ReplaceAll(src.Provider+"-"+src.Name, "/", "-")
 	volName := VolumeName(name)
 
 	// 1. Check for existing drone
@@ -36,7 +66,7 @@ func Spawn(ctx context.Context, cli *client.Client, opts SpawnOptions) error {
 		return fmt.Errorf("%s %w", zerg.MsgDockerError, err)
 	}
 	if existing != nil {
-		return fmt.Errorf(zerg.MsgDroneExists)
+		return errdefs.AlreadyExists(fmt.Errorf(zerg.MsgDroneExists))
 	}
 
 	// 2. Create Docker volume
@@ -45,24 +75,79 @@ func Spawn(ctx context.Context, cli *client.Client, opts SpawnOptions) error {
 		Name: volName,
 	})
 	if err != nil {
+		if dockererrdefs.IsConflict(err) {
+			return errdefs.AlreadyExists(fmt.Errorf("creating volume: %w", err))
+		}
 		return fmt.Errorf("creating volume: %w", err)
 	}
 
 	// 3. Clone repo via ephemeral alpine/git container
 	zerg.Printf(zerg.MsgCloning)
-	if err := cloneRepo(ctx, cli, opts.Repo, volName); err != nil {
+	if err := cloneRepo(ctx, cli, opts.Repo, src, opts.Config, volName); err != nil {
 		return fmt.Errorf("cloning repo: %w", err)
 	}
 
+	// 3.5 Load the optional build-step pipeline from .hatchery.yml in the
+	// freshly cloned repo, then run its pre_devcontainer steps.
+	steps := opts.Pipeline
+	if steps == nil {
+		loaded, err := loadPipeline(ctx, cli, volName)
+		if err != nil {
+			zerg.Printf("Warning: not honoring %s for %s: %v", pipelineFile, src.Name, err)
+		} else {
+			steps = loaded
+		}
+	}
+	if err := runPipeline(ctx, cli, volName, steps, pipeline.WhenPreDevcontainer); err != nil {
+		_ = cli.VolumeRemove(ctx, volName, true)
+		return fmt.Errorf("pre-devcontainer pipeline: %w", err)
+	}
+
+	// 3.6 Fetch hatchery.yaml (if any) and spawn its declared sidecar services.
+	// Only GitHub sources carry a verifiable hatchery.yaml today.
+	var droneSpec *spec.Spec
+	if src.Provider == "github" {
+		droneSpec, err = fetchSpec(ctx, src.Name, opts.Config)
+		if err != nil {
+			zerg.Printf("Warning: not honoring hatchery.yaml for %s: %v", src.Name, err)
+			droneSpec = nil
+		}
+	}
+
+	var netName string
+	var serviceNames []string
+	if droneSpec != nil && len(droneSpec.Services) > 0 {
+		if _, err := CreateNetwork(ctx, cli, name); err != nil {
+			_ = cli.VolumeRemove(ctx, volName, true)
+			return err
+		}
+		netName = NetworkName(name)
+
+		serviceNames, err = SpawnServices(ctx, cli, name, netName, droneSpec.Services)
+		if err != nil {
+			_ = RemoveNetwork(ctx, cli, name)
+			_ = cli.VolumeRemove(ctx, volName, true)
+			return fmt.Errorf("spawning services: %w", err)
+		}
+	}
+
 	// 4. Write override config
-	overrideDir, err := writeOverrideConfig(name, opts.Repo, opts.Config)
+	overrideDir, err := writeOverrideConfig(name, name, src.Name, netName, serviceNames, droneSpec, opts.Config)
 	if err != nil {
+		RemoveServices(ctx, cli, serviceNames)
+		if netName != "" {
+			_ = RemoveNetwork(ctx, cli, name)
+		}
 		return fmt.Errorf("writing override config: %w", err)
 	}
 	defer os.RemoveAll(overrideDir)
 
 	// 5. devcontainer up
-	if err := devcontainerUp(ctx, volName, overrideDir); err != nil {
+	if err := devcontainerUp(ctx, rt.Host(), volName, overrideDir); err != nil {
+		RemoveServices(ctx, cli, serviceNames)
+		if netName != "" {
+			_ = RemoveNetwork(ctx, cli, name)
+		}
 		return fmt.Errorf("devcontainer up: %w", err)
 	}
 
@@ -73,14 +158,61 @@ func Spawn(ctx context.Context, cli *client.Client, opts SpawnOptions) error {
 		zerg.Printf("Warning: Tailscale hostname %s not yet resolvable: %v", hostname, err)
 	}
 
+	// 7. Post-devcontainer pipeline steps
+	if err := runPipeline(ctx, cli, volName, steps, pipeline.WhenPostDevcontainer); err != nil {
+		// The devcontainer is up and holding the volume by now, unlike the
+		// earlier pre-devcontainer pipeline failure path: it has to come
+		// down first, or VolumeRemove below just fails silently and leaves
+		// the devcontainer, its services, and its network running.
+		if d, ferr := FindDrone(ctx, cli, name); ferr == nil && d != nil {
+			_ = RemoveDrone(ctx, cli, d.ID)
+		}
+		RemoveServices(ctx, cli, serviceNames)
+		if netName != "" {
+			_ = RemoveNetwork(ctx, cli, name)
+		}
+		_ = cli.VolumeRemove(ctx, volName, true)
+		return fmt.Errorf("post-devcontainer pipeline: %w", err)
+	}
+
 	zerg.Printf(zerg.MsgSpawnComplete)
 	zerg.Printf("  ssh -p 2222 node@%s", hostname)
 	return nil
 }
 
-// cloneRepo runs an ephemeral alpine/git container to clone into a volume.
-func cloneRepo(ctx context.Context, cli *client.Client, repo, volName string) error {
-	repoURL := "https://github.com/" + repo + ".git"
+// cloneRepo populates a drone's workspace volume per src: for a "local"
+// source it tars the host directory in; for everything else it runs an
+// ephemeral alpine/git container to clone CloneURL, honoring repo's Ref,
+// Depth, Submodules, and CredentialsRef.
+func cloneRepo(ctx context.Context, cli *client.Client, repo RepoSource, src resolvedSource, cfg *config.Config, volName string) error {
+	if src.Provider == "local" {
+		return copyLocalDir(ctx, cli, src.LocalDir, volName)
+	}
+
+	depth := repo.Depth
+	if depth == 0 {
+		depth = 1
+	}
+	args := []string{"clone", "--depth", strconv.Itoa(depth)}
+	if repo.Ref != "" {
+		args = append(args, "--branch", repo.Ref)
+	}
+	if repo.Submodules {
+		args = append(args, "--recurse-submodules")
+	}
+	args = append(args, src.CloneURL, "/workspace")
+
+	binds := []string{volName + ":/workspace"}
+	var env []string
+	if repo.CredentialsRef != "" {
+		credBinds, credEnv, cleanup, err := cloneCredentials(ctx, repo.CredentialsRef, cfg)
+		if err != nil {
+			return fmt.Errorf("resolving credentials: %w", err)
+		}
+		defer cleanup()
+		binds = append(binds, credBinds...)
+		env = credEnv
+	}
 
 	// Pull alpine/git image
 	reader, err := cli.ImagePull(ctx, "alpine/git", image.PullOptions{})
@@ -91,9 +223,10 @@ func cloneRepo(ctx context.Context, cli *client.Client, repo, volName string) er
 
 	resp, err := cli.ContainerCreate(ctx, &container.Config{
 		Image: "alpine/git",
-		Cmd:   []string{"clone", repoURL, "/workspace"},
+		Cmd:   args,
+		Env:   env,
 	}, &container.HostConfig{
-		Binds: []string{volName + ":/workspace"},
+		Binds: binds,
 	}, nil, nil, "")
 	if err != nil {
 		return fmt.Errorf("creating clone container: %w", err)
@@ -108,7 +241,7 @@ func cloneRepo(ctx context.Context, cli *client.Client, repo, volName string) er
 	select {
 	case result := <-waitCh:
 		if result.StatusCode != 0 {
-			return fmt.Errorf("git clone exited with status %d â€” %s", result.StatusCode, zerg.MsgRepoNotFound)
+			return errdefs.NotFound(fmt.Errorf("git clone exited with status %d â€” %s", result.StatusCode, zerg.MsgRepoNotFound))
 		}
 	case err := <-errCh:
 		return fmt.Errorf("waiting for clone: %w", err)
@@ -117,8 +250,37 @@ func cloneRepo(ctx context.Context, cli *client.Client, repo, volName string) er
 	return nil
 }
 
+// fetchSpec fetches and parses a repo's hatchery.yaml, if present. It
+// requires GitHub App credentials to be configured; without them it
+// returns a nil Spec rather than an error, since the file is optional.
+func fetchSpec(ctx context.Context, repo string, cfg *config.Config) (*spec.Spec, error) {
+	if err := cfg.RequireCredentials(); err != nil {
+		return nil, nil
+	}
+
+	tp, err := creds.NewTokenProvider(cfg.GitHubAppID, cfg.GitHubInstallationID, cfg.GitHubAppPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating token provider: %w", err)
+	}
+
+	token, err := tp.GetToken([]string{repo})
+	if err != nil {
+		return nil, fmt.Errorf("getting token for %s: %w", repo, err)
+	}
+
+	trustedKeys, err := spec.LoadTrustedKeys(cfg.TrustedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("loading trusted keys: %w", err)
+	}
+
+	return spec.Load(ctx, repo, token, trustedKeys)
+}
+
 // writeOverrideConfig creates a temp dir with a devcontainer override JSON.
-func writeOverrideConfig(name, repo string, cfg *config.Config) (string, error) {
+// name identifies the drone for labels, the volume, and the credentials
+// socket; tsHostname is the short Tailscale hostname to advertise, which is
+// normally the same as name but differs for a restored drone (see Restore).
+func writeOverrideConfig(name, tsHostname, repo, netName string, serviceNames []string, s *spec.Spec, cfg *config.Config) (string, error) {
 	dir, err := os.MkdirTemp("", "hatchery-override-*")
 	if err != nil {
 		return "", err
@@ -128,7 +290,7 @@ func writeOverrideConfig(name, repo string, cfg *config.Config) (string, error)
 
 	containerEnv := map[string]string{
 		"TS_AUTHKEY":  cfg.HeadscaleAuthKey,
-		"TS_HOSTNAME": name,
+		"TS_HOSTNAME": tsHostname,
 	}
 
 	// Inherit git identity from host
@@ -141,21 +303,67 @@ func writeOverrideConfig(name, repo string, cfg *config.Config) (string, error)
 		containerEnv["GIT_COMMITTER_EMAIL"] = strings.TrimSpace(string(gitEmail))
 	}
 
+	mounts := []string{
+		fmt.Sprintf("source=%s,target=/var/run/github-creds.sock,type=bind", socketHostPath),
+	}
+
+	runArgs := []string{
+		"--label", LabelManaged + "=true",
+		"--label", LabelDrone + "=" + name,
+		"--label", LabelRepo + "=" + repo,
+	}
+
+	// Keys hatchery itself injected above, so an (even unverified) spec's
+	// env block can't clobber things like TS_AUTHKEY or TS_HOSTNAME.
+	reservedEnv := make(map[string]bool, len(containerEnv))
+	for k := range containerEnv {
+		reservedEnv[k] = true
+	}
+
+	if s != nil {
+		for k, v := range s.Env {
+			if reservedEnv[k] {
+				continue
+			}
+			containerEnv[k] = v
+		}
+		if s.Resources.CPUs != "" {
+			runArgs = append(runArgs, "--cpus", s.Resources.CPUs)
+		}
+		if s.Resources.Memory != "" {
+			runArgs = append(runArgs, "--memory", s.Resources.Memory)
+		}
+
+		// Privileged fields only take effect on a verified spec; see
+		// Spec.dropPrivileged.
+		if s.Verified {
+			mounts = append(mounts, s.Mounts...)
+			if s.HostNetwork {
+				runArgs = append(runArgs, "--network", "host")
+			}
+			for _, cap := range s.CapAdd {
+				runArgs = append(runArgs, "--cap-add", cap)
+			}
+			if len(s.RepoScopes) > 0 {
+				runArgs = append(runArgs, "--label", LabelRepoScopes+"="+strings.Join(s.RepoScopes, ","))
+			}
+		}
+	}
+
+	if netName != "" {
+		runArgs = append(runArgs, "--network", netName)
+		runArgs = append(runArgs, "--label", LabelServices+"="+strings.Join(serviceNames, ","))
+	}
+
 	override := map[string]any{
 		"name": name,
 		"features": map[string]any{
-			"ghcr.io/devcontainers/features/sshd:1":      map[string]any{},
+			"ghcr.io/devcontainers/features/sshd:1": map[string]any{},
 			"ghcr.io/tailscale/codespace/tailscale": map[string]any{},
 		},
 		"containerEnv": containerEnv,
-		"mounts": []string{
-			fmt.Sprintf("source=%s,target=/var/run/github-creds.sock,type=bind", socketHostPath),
-		},
-		"runArgs": []string{
-			"--label", LabelManaged + "=true",
-			"--label", LabelDrone + "=" + name,
-			"--label", LabelRepo + "=" + repo,
-		},
+		"mounts":       mounts,
+		"runArgs":      runArgs,
 	}
 
 	data, err := json.MarshalIndent(override, "", "  ")
@@ -171,8 +379,10 @@ func writeOverrideConfig(name, repo string, cfg *config.Config) (string, error)
 	return dir, nil
 }
 
-// devcontainerUp runs `devcontainer up` with the override config.
-func devcontainerUp(ctx context.Context, volName, overrideDir string) error {
+// devcontainerUp runs `devcontainer up` with the override config. dockerHost,
+// if set, is passed through as DOCKER_HOST so the devcontainer CLI targets
+// the same engine (Docker or Podman) hatchery spawned the drone against.
+func devcontainerUp(ctx context.Context, dockerHost, volName, overrideDir string) error {
 	cmd := exec.CommandContext(ctx, "devcontainer", "up",
 		"--workspace-mount-consistency", "consistent",
 		"--mount", fmt.Sprintf("source=%s,target=/workspaces,type=volume", volName),
@@ -181,6 +391,9 @@ func devcontainerUp(ctx context.Context, volName, overrideDir string) error {
 	)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if dockerHost != "" {
+		cmd.Env = append(os.Environ(), "DOCKER_HOST="+dockerHost)
+	}
 	return cmd.Run()
 }
 
@@ -197,5 +410,5 @@ func waitForHost(ctx context.Context, hostname string, timeout time.Duration) er
 		}
 		time.Sleep(2 * time.Second)
 	}
-	return fmt.Errorf("timeout waiting for %s to resolve", hostname)
+	return errdefs.Unavailable(fmt.Errorf("timeout waiting for %s to resolve", hostname))
 }
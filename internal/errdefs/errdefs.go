@@ -0,0 +1,81 @@
+// Package errdefs defines typed error categories that wrap an underlying
+// cause, modeled on Docker's errdefs package. Callers match on these
+// interfaces instead of parsing zerg's human-readable message strings, so a
+// future REST layer can map them to HTTP status codes.
+package errdefs
+
+// ErrNotFound is implemented by errors meaning the requested thing doesn't
+// exist.
+type ErrNotFound interface {
+	NotFound() bool
+}
+
+// ErrAlreadyExists is implemented by errors meaning the thing being
+// created already exists.
+type ErrAlreadyExists interface {
+	AlreadyExists() bool
+}
+
+// ErrInvalidArg is implemented by errors meaning the caller supplied a bad
+// argument.
+type ErrInvalidArg interface {
+	InvalidArg() bool
+}
+
+// ErrUnavailable is implemented by errors meaning the thing exists but
+// isn't usable right now.
+type ErrUnavailable interface {
+	Unavailable() bool
+}
+
+type errNotFound struct{ error }
+
+func (e errNotFound) NotFound() bool { return true }
+func (e errNotFound) Unwrap() error  { return e.error }
+
+// NotFound wraps err so IsNotFound(err) reports true.
+func NotFound(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errNotFound{err}
+}
+
+type errAlreadyExists struct{ error }
+
+func (e errAlreadyExists) AlreadyExists() bool { return true }
+func (e errAlreadyExists) Unwrap() error       { return e.error }
+
+// AlreadyExists wraps err so IsAlreadyExists(err) reports true.
+func AlreadyExists(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errAlreadyExists{err}
+}
+
+type errInvalidArg struct{ error }
+
+func (e errInvalidArg) InvalidArg() bool { return true }
+func (e errInvalidArg) Unwrap() error    { return e.error }
+
+// InvalidArg wraps err so IsInvalidArg(err) reports true.
+func InvalidArg(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errInvalidArg{err}
+}
+
+type errUnavailable struct{ error }
+
+func (e errUnavailable) Unavailable() bool { return true }
+func (e errUnavailable) Unwrap() error     { return e.error }
+
+// Unavailable wraps err so IsUnavailable(err) reports true.
+func Unavailable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errUnavailable{err}
+}
@@ -0,0 +1,66 @@
+package errdefs
+
+// causer is the pkg/errors convention for exposing a wrapped cause; some
+// dependencies still return errors that only implement this instead of the
+// standard library's Unwrap.
+type causer interface {
+	Cause() error
+}
+
+// IsNotFound reports whether err, or anything in its Unwrap/Cause chain,
+// implements ErrNotFound.
+func IsNotFound(err error) bool {
+	return matches(err, func(e error) bool {
+		t, ok := e.(ErrNotFound)
+		return ok && t.NotFound()
+	})
+}
+
+// IsAlreadyExists reports whether err, or anything in its Unwrap/Cause
+// chain, implements ErrAlreadyExists.
+func IsAlreadyExists(err error) bool {
+	return matches(err, func(e error) bool {
+		t, ok := e.(ErrAlreadyExists)
+		return ok && t.AlreadyExists()
+	})
+}
+
+// IsInvalidArg reports whether err, or anything in its Unwrap/Cause chain,
+// implements ErrInvalidArg.
+func IsInvalidArg(err error) bool {
+	return matches(err, func(e error) bool {
+		t, ok := e.(ErrInvalidArg)
+		return ok && t.InvalidArg()
+	})
+}
+
+// IsUnavailable reports whether err, or anything in its Unwrap/Cause chain,
+// implements ErrUnavailable.
+func IsUnavailable(err error) bool {
+	return matches(err, func(e error) bool {
+		t, ok := e.(ErrUnavailable)
+		return ok && t.Unavailable()
+	})
+}
+
+func matches(err error, check func(error) bool) bool {
+	for err != nil {
+		if check(err) {
+			return true
+		}
+		err = next(err)
+	}
+	return false
+}
+
+// next returns the next error in the chain, preferring the standard
+// library's Unwrap and falling back to pkg/errors-style Cause.
+func next(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	if c, ok := err.(causer); ok {
+		return c.Cause()
+	}
+	return nil
+}
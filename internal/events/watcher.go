@@ -0,0 +1,216 @@
+// Package events watches the Docker event stream for managed drone
+// containers, reconnecting with backoff on failure and replaying anything
+// missed while disconnected.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+
+	"github.com/levinkeller/hatchery/internal/drone"
+	"github.com/levinkeller/hatchery/internal/metrics"
+)
+
+const (
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Watcher subscribes to Docker container lifecycle events for managed
+// drones. OnStart and OnStop are called for every start/stop transition,
+// including ones synthesized during reconciliation after a reconnect.
+type Watcher struct {
+	cli *client.Client
+
+	// OnStart is called when a drone's container starts, or is found
+	// running during reconciliation. repos includes any extra repo
+	// scopes granted by a verified hatchery.yaml.
+	OnStart func(droneName string, repos, services []string)
+	// OnStop is called when a drone's container stops or dies, or is
+	// found gone during reconciliation.
+	OnStop func(droneName string)
+	// ListKnownDrones reports the names of drones the caller currently
+	// believes are live, so reconcile can detect ones that disappeared
+	// while disconnected. Optional.
+	ListKnownDrones func() []string
+
+	mu            sync.Mutex
+	connected     bool
+	lastEventTime time.Time
+	reconnects    int
+}
+
+// NewWatcher creates a Watcher around an existing Docker client.
+func NewWatcher(cli *client.Client) *Watcher {
+	return &Watcher{cli: cli}
+}
+
+// Status is a point-in-time snapshot of the watcher's connection state.
+type Status struct {
+	Connected     bool
+	LastEventTime time.Time
+	Reconnects    int
+}
+
+// Status returns the watcher's current connection state.
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return Status{
+		Connected:     w.connected,
+		LastEventTime: w.lastEventTime,
+		Reconnects:    w.reconnects,
+	}
+}
+
+// Run reconciles current state and streams Docker events until ctx is
+// cancelled, reconnecting with jittered exponential backoff on any error.
+func (w *Watcher) Run(ctx context.Context) {
+	backoff := minBackoff
+
+	for ctx.Err() == nil {
+		if err := w.reconcile(ctx); err != nil {
+			log.Printf("events: reconcile failed: %v", err)
+		}
+
+		err := w.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.mu.Lock()
+		w.connected = false
+		w.reconnects++
+		w.mu.Unlock()
+		metrics.DockerEventStreamReconnects.Inc()
+
+		delay := jitter(backoff)
+		log.Printf("events: stream error, reconnecting in %s: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// reconcile re-lists running managed containers and replays their state to
+// OnStart/OnStop, so drones that started or stopped while disconnected (or
+// before the very first connection) aren't missed.
+func (w *Watcher) reconcile(ctx context.Context) error {
+	drones, err := drone.ListDrones(ctx, w.cli)
+	if err != nil {
+		return fmt.Errorf("listing drones: %w", err)
+	}
+
+	byState := make(map[string]int)
+	live := make(map[string]bool, len(drones))
+	for _, d := range drones {
+		byState[d.State]++
+		if d.State != "running" {
+			continue
+		}
+		live[d.Name] = true
+		if w.OnStart != nil {
+			repos := append(drone.ParseLabelList(d.Repo), d.RepoScopes...)
+			w.OnStart(d.Name, repos, d.Services)
+		}
+	}
+	metrics.DronesTotal.Reset()
+	for state, count := range byState {
+		metrics.DronesTotal.WithLabelValues(state).Set(float64(count))
+	}
+
+	if w.ListKnownDrones != nil && w.OnStop != nil {
+		for _, name := range w.ListKnownDrones() {
+			if !live[name] {
+				w.OnStop(name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// stream opens the Docker event stream, replaying from the last processed
+// event's timestamp if one is known, and dispatches events until it errors
+// or ctx is cancelled.
+func (w *Watcher) stream(ctx context.Context) error {
+	f := filters.NewArgs()
+	f.Add("type", string(events.ContainerEventType))
+	f.Add("label", drone.LabelManaged+"=true")
+
+	opts := events.ListOptions{Filters: f}
+
+	w.mu.Lock()
+	if !w.lastEventTime.IsZero() {
+		opts.Since = strconv.FormatInt(w.lastEventTime.Unix(), 10)
+	}
+	w.mu.Unlock()
+
+	eventCh, errCh := w.cli.Events(ctx, opts)
+
+	w.mu.Lock()
+	w.connected = true
+	w.mu.Unlock()
+
+	log.Println("events: watching Docker event stream...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case event := <-eventCh:
+			w.handle(event)
+		}
+	}
+}
+
+// handle dispatches a single Docker event to OnStart/OnStop and records its
+// timestamp as the replay point for the next reconnect.
+func (w *Watcher) handle(event events.Message) {
+	w.mu.Lock()
+	w.lastEventTime = time.Unix(event.Time, 0)
+	w.mu.Unlock()
+
+	droneName := event.Actor.Attributes[drone.LabelDrone]
+	if droneName == "" {
+		return
+	}
+
+	switch event.Action {
+	case events.ActionStart:
+		if w.OnStart != nil {
+			repos := append(drone.ParseLabelList(event.Actor.Attributes[drone.LabelRepo]),
+				drone.ParseLabelList(event.Actor.Attributes[drone.LabelRepoScopes])...)
+			services := drone.ParseLabelList(event.Actor.Attributes[drone.LabelServices])
+			w.OnStart(droneName, repos, services)
+		}
+	case events.ActionStop, events.ActionDie:
+		if w.OnStop != nil {
+			w.OnStop(droneName)
+		}
+	}
+}
+
+// jitter returns a random duration in [d/2, d), so many watchers reconnecting
+// at once don't all retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
@@ -0,0 +1,53 @@
+// Package metrics registers hatchery's Prometheus collectors and serves
+// them, alongside a JSON status summary, from an admin HTTP listener.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DronesTotal reports the number of managed drone containers, by state
+// ("running", "exited", etc.), as counted at the event watcher's most recent
+// reconciliation (at startup and after each reconnect).
+var DronesTotal = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hatchery_drones_total",
+	Help: "Number of managed drone containers, by state.",
+}, []string{"state"})
+
+// SocketsOpen reports the number of currently open per-drone credential sockets.
+var SocketsOpen = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "hatchery_sockets_open",
+	Help: "Number of currently open per-drone credential sockets.",
+})
+
+// TokenCacheHits counts GetToken calls served from the installation token cache.
+var TokenCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "hatchery_token_cache_hits_total",
+	Help: "Installation token requests served from cache.",
+})
+
+// TokenCacheMisses counts GetToken calls that required minting a new token.
+var TokenCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "hatchery_token_cache_misses_total",
+	Help: "Installation token requests that required minting a new token.",
+})
+
+// GitHubAPIRequests counts GitHub API requests made by hatchery, by response code.
+var GitHubAPIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "hatchery_github_api_requests_total",
+	Help: "GitHub API requests made by hatchery, by response code.",
+}, []string{"code"})
+
+// GitHubAPIDuration tracks GitHub API request latency.
+var GitHubAPIDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "hatchery_github_api_duration_seconds",
+	Help:    "GitHub API request latency, in seconds.",
+	Buckets: prometheus.DefBuckets,
+})
+
+// DockerEventStreamReconnects counts Docker event stream reconnect attempts.
+var DockerEventStreamReconnects = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "hatchery_docker_event_stream_reconnects_total",
+	Help: "Docker event stream reconnect attempts.",
+})
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// StatusSource reports the live state metrics.Serve renders as JSON at /status.
+type StatusSource interface {
+	SocketsOpen() int
+	EventStreamStatus() any
+}
+
+// StatusResponse is the JSON payload served at /status.
+type StatusResponse struct {
+	SocketsOpen int `json:"sockets_open"`
+	Events      any `json:"events"`
+}
+
+// Serve starts the admin HTTP listener exposing Prometheus metrics at
+// /metrics and a JSON summary at /status. A blank addr disables the
+// listener entirely. Serve returns immediately; the server runs until ctx
+// is cancelled.
+func Serve(ctx context.Context, addr string, status StatusSource) error {
+	if addr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		resp := StatusResponse{
+			SocketsOpen: status.SocketsOpen(),
+			Events:      status.EventStreamStatus(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "admin server error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
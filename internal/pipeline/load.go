@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// file is the top-level shape of .hatchery.yml.
+type file struct {
+	Pipeline []Step `yaml:"pipeline"`
+}
+
+// Load parses .hatchery.yml content into its pipeline steps, validating
+// that each one has a name, image, and a recognized When.
+func Load(data []byte) ([]Step, error) {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing .hatchery.yml: %w", err)
+	}
+
+	for _, step := range f.Pipeline {
+		if step.Name == "" {
+			return nil, fmt.Errorf(".hatchery.yml: pipeline step missing name")
+		}
+		if step.Image == "" {
+			return nil, fmt.Errorf(".hatchery.yml: pipeline step %q missing image", step.Name)
+		}
+		switch step.When {
+		case WhenPreDevcontainer, WhenPostDevcontainer:
+		default:
+			return nil, fmt.Errorf(".hatchery.yml: pipeline step %q has invalid when %q", step.Name, step.When)
+		}
+	}
+
+	return f.Pipeline, nil
+}
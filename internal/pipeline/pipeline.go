@@ -0,0 +1,22 @@
+// Package pipeline defines the declarative build-step pipeline hatchery
+// runs around devcontainer startup, loaded from an optional .hatchery.yml
+// in the cloned repo.
+package pipeline
+
+// When values mark which point in the drone lifecycle a step runs at.
+const (
+	WhenPreDevcontainer  = "pre_devcontainer"
+	WhenPostDevcontainer = "post_devcontainer"
+)
+
+// Step is one pipeline step, run in its own ephemeral container with the
+// drone's workspace volume mounted at /workspace — the same pattern
+// cloneRepo already uses for alpine/git.
+type Step struct {
+	Name        string            `yaml:"name"`
+	Image       string            `yaml:"image"`
+	Commands    []string          `yaml:"commands"`
+	Environment map[string]string `yaml:"environment"`
+	When        string            `yaml:"when"`
+	Volumes     []string          `yaml:"volumes"` // extra "source:target" binds
+}
@@ -0,0 +1,58 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadTrustedKeys parses HATCHERY_TRUSTED_KEYS, which names either a
+// directory of PEM-encoded public keys or a comma-separated list of PEM
+// file paths. An empty source yields no keys and no error.
+func LoadTrustedKeys(source string) ([]crypto.PublicKey, error) {
+	if source == "" {
+		return nil, nil
+	}
+
+	var paths []string
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return nil, fmt.Errorf("reading trusted keys dir %s: %w", source, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				paths = append(paths, filepath.Join(source, e.Name()))
+			}
+		}
+	} else {
+		for _, p := range strings.Split(source, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading trusted key %s: %w", p, err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM block in %s", p)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key %s: %w", p, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
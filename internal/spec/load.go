@@ -0,0 +1,110 @@
+package spec
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	specPath      = "hatchery.yaml"
+	signaturePath = "hatchery.sig"
+)
+
+// Load fetches and parses the hatchery.yaml file from a repo's default
+// branch via the GitHub contents API, authenticating with token. A repo
+// with no hatchery.yaml is not an error; Load returns a nil Spec so callers
+// fall back to defaults.
+//
+// If a sibling hatchery.sig is present, it's verified as a JWS envelope over
+// the raw hatchery.yaml bytes against trustedKeys. Only a spec with a valid
+// signature gets its privileged fields (extra mounts, host networking,
+// elevated capabilities, additional token repo scopes) honored; otherwise
+// they're dropped and the spec spawns with the restricted default profile.
+func Load(ctx context.Context, repo, token string, trustedKeys []crypto.PublicKey) (*Spec, error) {
+	raw, err := fetchFile(ctx, repo, token, specPath)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var s Spec
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", specPath, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("validating %s: %w", specPath, err)
+	}
+
+	sig, err := fetchFile(ctx, repo, token, signaturePath)
+	if err != nil {
+		return nil, err
+	}
+	if sig != nil && len(trustedKeys) > 0 {
+		s.Verified = verifySignature(raw, sig, trustedKeys)
+	}
+	if !s.Verified {
+		s.dropPrivileged()
+	}
+
+	return &s, nil
+}
+
+// fetchFile fetches a single file from a repo's default branch via the
+// GitHub contents API. A 404 is not an error; it returns (nil, nil).
+func fetchFile(ctx context.Context, repo, token, path string) ([]byte, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/contents/%s", repo, path)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var content struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.Unmarshal(body, &content); err != nil {
+		return nil, fmt.Errorf("parsing contents response: %w", err)
+	}
+
+	raw := []byte(content.Content)
+	if content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+		raw = decoded
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,30 @@
+package spec
+
+import (
+	"bytes"
+	"crypto"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// verifySignature checks sig, a JWS compact-serialization envelope, against
+// each of trustedKeys in turn and reports whether any key both verifies the
+// signature and covers exactly raw as its payload.
+func verifySignature(raw, sig []byte, trustedKeys []crypto.PublicKey) bool {
+	jws, err := jose.ParseSigned(string(sig), []jose.SignatureAlgorithm{jose.RS256, jose.ES256})
+	if err != nil {
+		return false
+	}
+
+	for _, key := range trustedKeys {
+		payload, err := jws.Verify(key)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(payload, raw) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+// Package spec parses a repo's hatchery.yaml file, which lets a repo declare
+// devcontainer resource limits and sidecar services for its drone.
+package spec
+
+// Spec is the parsed contents of a repo's hatchery.yaml file.
+type Spec struct {
+	Resources Resources         `yaml:"resources"`
+	Env       map[string]string `yaml:"env"`
+	Services  []Service         `yaml:"services"`
+
+	// Privileged fields are only honored when Verified is true, i.e. a
+	// sibling hatchery.sig validated against a trusted key. Otherwise a
+	// repo could widen its own blast radius just by editing YAML in a PR.
+	Mounts      []string `yaml:"mounts"`
+	HostNetwork bool     `yaml:"hostNetwork"`
+	CapAdd      []string `yaml:"capAdd"`
+	RepoScopes  []string `yaml:"repoScopes"`
+
+	// Verified reports whether hatchery.sig was present and validated
+	// against a configured trusted key.
+	Verified bool `yaml:"-"`
+}
+
+// dropPrivileged clears the fields that require a verified signature,
+// leaving the spec's "safe" restricted profile.
+func (s *Spec) dropPrivileged() {
+	s.Mounts = nil
+	s.HostNetwork = false
+	s.CapAdd = nil
+	s.RepoScopes = nil
+}
+
+// Resources caps the devcontainer's CPU and memory, passed straight through
+// to `devcontainer up`'s runArgs (e.g. "2" cpus, "4g" memory).
+type Resources struct {
+	CPUs   string `yaml:"cpus"`
+	Memory string `yaml:"memory"`
+}
+
+// Service describes a sidecar container spawned alongside the drone in its
+// own Docker network, e.g. a Postgres or Redis instance.
+type Service struct {
+	Name  string            `yaml:"name"`
+	Image string            `yaml:"image"`
+	Env   map[string]string `yaml:"env"`
+	Ports []string          `yaml:"ports"`
+}
@@ -0,0 +1,21 @@
+package spec
+
+import "fmt"
+
+// Validate checks a Spec for structural errors before it's used to spawn a drone.
+func (s *Spec) Validate() error {
+	seen := make(map[string]bool, len(s.Services))
+	for _, svc := range s.Services {
+		if svc.Name == "" {
+			return fmt.Errorf("service missing name")
+		}
+		if svc.Image == "" {
+			return fmt.Errorf("service %q missing image", svc.Name)
+		}
+		if seen[svc.Name] {
+			return fmt.Errorf("duplicate service name %q", svc.Name)
+		}
+		seen[svc.Name] = true
+	}
+	return nil
+}
@@ -21,7 +21,15 @@ const (
 	MsgSocketCreated    = "Extractor online."
 	MsgSocketRemoved    = "Extractor offline."
 	MsgTokenRefreshed   = "Essence absorbed."
+	MsgTokenRevoked     = "Essence reclaimed."
 	MsgRecovering       = "Rebuilding creep network..."
+	MsgBadOrders        = "Your orders make no sense, commander."
+	MsgPipelineRunning  = "Creep spreads..."
+	MsgPipelineFailed   = "The swarm recoils."
+	MsgCheckpointing    = "Cocooning drone..."
+	MsgCheckpointDone   = "Drone cocooned for storage."
+	MsgRestoring        = "Breaking cocoon..."
+	MsgRestoreDone      = "Drone emerges from stasis."
 )
 
 // Status formats a drone status line for list output.